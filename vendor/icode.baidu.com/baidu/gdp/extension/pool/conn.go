@@ -29,8 +29,9 @@ func (nf NewConnFunc) Trans(p *connPool) NewElementFunc {
 
 // NewConnPool 创建新的 net.Conn 的连接池
 func NewConnPool(option *Option, newFunc NewConnFunc) ConnPool {
-	p := &connPool{}
+	p := &connPool{newFunc: newFunc}
 	p.raw = NewSimplePool(option, newFunc.Trans(p))
+	p.startIdleCheck(*option)
 	return p
 }
 
@@ -48,6 +49,87 @@ var _ ConnPool = (*connPool)(nil)
 // connPool 网络连接池
 type connPool struct {
 	raw SimplePool
+
+	newFunc NewConnFunc
+
+	idleCheckCancel context.CancelFunc
+	idleCheckWG     sync.WaitGroup
+}
+
+// startIdleCheck 按 Option.IdleCheckInterval 启动一个后台协程，
+// 定期巡检空闲连接并补充到 Option.MinIdleConns，Option.IdleCheckInterval<=0 则不启动
+func (cp *connPool) startIdleCheck(opt Option) {
+	if opt.IdleCheckInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cp.idleCheckCancel = cancel
+	cp.idleCheckWG.Add(1)
+	go cp.idleCheckLoop(ctx, opt)
+}
+
+func (cp *connPool) idleCheckLoop(ctx context.Context, opt Option) {
+	defer cp.idleCheckWG.Done()
+
+	ticker := time.NewTicker(opt.IdleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cp.checkIdle(ctx, opt)
+		}
+	}
+}
+
+// checkIdle 巡检一遍当前空闲连接，复用 pConn.PEActive 已有的健康检查和
+// MaxLifeTime/MaxIdleTime 判断逻辑；不健康的连接在回调里直接 Close 掉，通过
+// 既有的 put/close 逻辑淘汰，回调本身始终返回 nil，确保 Range 会走完所有空闲
+// 连接而不是被某一个失效连接提前打断。之后按需要把空闲连接数补回 MinIdleConns，
+// 这里必须走 newFunc 直接拨号再 Put 进池子，不能借道 Get：Get 在池子里还有空闲
+// 连接时会优先把幸存的连接还回来而不是新建，导致这里只是在反复回收同一个连接，
+// 并没有真正把空闲连接数补上去
+// ctx 在 Close() 时会被取消，用来保证拨号不会让后台协程永久阻塞
+func (cp *connPool) checkIdle(ctx context.Context, opt Option) {
+	idle := 0
+	_ = cp.raw.Range(func(el Element) error {
+		if pa, ok := el.(peActiveChecker); ok {
+			if err := pa.PEActive(); err != nil {
+				if conn, ok := el.(net.Conn); ok {
+					_ = conn.Close()
+				}
+				return nil
+			}
+		}
+		idle++
+		return nil
+	})
+
+	for idle < opt.MinIdleConns {
+		if ctx.Err() != nil {
+			return
+		}
+		raw, err := cp.newFunc(ctx)
+		if err != nil {
+			return
+		}
+		if err := cp.Put(newPConn(raw, cp)); err != nil {
+			return
+		}
+		idle++
+	}
+}
+
+// stopIdleCheck 停止后台巡检协程并等待其退出
+func (cp *connPool) stopIdleCheck() {
+	if cp.idleCheckCancel == nil {
+		return
+	}
+	cp.idleCheckCancel()
+	cp.idleCheckWG.Wait()
 }
 
 // Get get
@@ -72,6 +154,7 @@ func (cp *connPool) Range(fn func(net.Conn) error) error {
 
 // Close close pool
 func (cp *connPool) Close() error {
+	cp.stopIdleCheck()
 	return cp.raw.Close()
 }
 
@@ -219,6 +302,12 @@ func (c *pConn) PERawClose() error {
 	return c.raw.Close()
 }
 
+// peActiveChecker 只有 *pConn 会实现，供 connPool/connGroup 的后台巡检复用
+// PEActive 里已经做好的 lastErr/MaxLifeTime/MaxIdleTime/HealthChecker 判断
+type peActiveChecker interface {
+	PEActive() error
+}
+
 func (c *pConn) PEActive() error {
 	c.mu.RLock()
 
@@ -239,8 +328,9 @@ func (c *pConn) PEActive() error {
 		}
 	}
 
-	// 检查底层连接是否有效
-	if err := connCheck(c.getRawConn()); err != nil {
+	// 检查底层连接是否有效，检查策略可以通过 Option.HealthChecker 自定义
+	checker := resolveHealthChecker(c.pool.Option())
+	if err := checker.Check(context.Background(), c.getRawConn()); err != nil {
 		return err
 	}
 
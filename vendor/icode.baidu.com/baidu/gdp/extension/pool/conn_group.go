@@ -7,6 +7,8 @@ package pool
 import (
 	"context"
 	"net"
+	"sync"
+	"time"
 )
 
 // GroupNewConnFunc 给 Group 创建新的 pool
@@ -26,9 +28,19 @@ func (gn GroupNewConnFunc) trans() GroupNewElementFunc {
 
 // NewConnPoolGroup 创建新的 Group
 func NewConnPoolGroup(opt *Option, gn GroupNewConnFunc) ConnPoolGroup {
-	return &connGroup{
-		raw: NewSimplePoolGroup(opt, gn.trans()),
+	cg := &connGroup{
+		raw:     NewSimplePoolGroup(opt, gn.trans()),
+		newFunc: gn,
 	}
+	cg.startIdleCheck(*opt)
+	return cg
+}
+
+// groupSubPools 只有支持按 key 暴露子连接池的 SimplePoolGroup 实现才需要满足，
+// 供后台巡检在手动拨号补齐 MinIdleConns 时，把新连接通过对应地址的子连接池直接
+// Put 回去，而不必借道 Get
+type groupSubPools interface {
+	Pool(key interface{}) SimplePool
 }
 
 // ConnPoolGroup 按照 key 分组的 连接池
@@ -44,6 +56,107 @@ var _ ConnPoolGroup = (*connGroup)(nil)
 
 type connGroup struct {
 	raw SimplePoolGroup
+
+	newFunc GroupNewConnFunc
+
+	idleCheckCancel context.CancelFunc
+	idleCheckWG     sync.WaitGroup
+}
+
+// startIdleCheck 和 connPool.startIdleCheck 一样，按 Option.IdleCheckInterval
+// 启动一个后台协程，把同一套巡检逻辑分摊到 Group 下的每个子连接池
+func (cg *connGroup) startIdleCheck(opt Option) {
+	if opt.IdleCheckInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cg.idleCheckCancel = cancel
+	cg.idleCheckWG.Add(1)
+	go cg.idleCheckLoop(ctx, opt)
+}
+
+func (cg *connGroup) idleCheckLoop(ctx context.Context, opt Option) {
+	defer cg.idleCheckWG.Done()
+
+	ticker := time.NewTicker(opt.IdleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cg.checkIdle(ctx, opt)
+		}
+	}
+}
+
+// checkIdle 巡检 Group 内所有子连接池当前存活的空闲连接，复用 pConn.PEActive
+// 已有的健康检查和 MaxLifeTime/MaxIdleTime 判断逻辑；不健康的连接在回调里直接
+// Close 掉，通过既有的 put/close 逻辑淘汰，回调始终返回 nil，确保一个地址上的
+// 失效连接不会打断 Range 对其它地址的遍历。之后再按各自 RemoteAddr 把空闲连接数
+// 补回 MinIdleConns，这里必须通过 newFunc 直接拨号、再用 groupSubPools 暴露出的
+// 子连接池 Put 回去，不能借道 Get：Get 在对应地址还有空闲连接时会优先把幸存的
+// 连接还回来而不是新建，导致这里只是在反复回收同一个连接，并没有真正把空闲连接数
+// 补上去；如果底层 SimplePoolGroup 实现没有暴露 groupSubPools，则放弃补齐。
+// ctx 在 Close() 时会被取消，用来保证拨号不会让后台协程永久阻塞
+func (cg *connGroup) checkIdle(ctx context.Context, opt Option) {
+	addrs := make(map[string]net.Addr)
+	idle := make(map[string]int)
+
+	_ = cg.raw.Range(func(el Element) error {
+		conn := el.(net.Conn)
+		addr := conn.RemoteAddr()
+		addrs[addr.String()] = addr
+
+		if pa, ok := el.(peActiveChecker); ok {
+			if err := pa.PEActive(); err != nil {
+				_ = conn.Close()
+				return nil
+			}
+		}
+		idle[addr.String()]++
+		return nil
+	})
+
+	if opt.MinIdleConns <= 0 {
+		return
+	}
+
+	gp, ok := cg.raw.(groupSubPools)
+	if !ok {
+		return
+	}
+
+	for key, addr := range addrs {
+		for idle[key] < opt.MinIdleConns {
+			if ctx.Err() != nil {
+				return
+			}
+			raw, err := cg.newFunc(addr)(ctx)
+			if err != nil {
+				break
+			}
+			sub, ok := gp.Pool(addr).(NewElementNeed)
+			if !ok {
+				return
+			}
+			if err := sub.Put(newPConn(raw, sub)); err != nil {
+				break
+			}
+			idle[key]++
+		}
+	}
+}
+
+// stopIdleCheck 停止后台巡检协程并等待其退出
+func (cg *connGroup) stopIdleCheck() {
+	if cg.idleCheckCancel == nil {
+		return
+	}
+	cg.idleCheckCancel()
+	cg.idleCheckWG.Wait()
 }
 
 func (cg *connGroup) Range(fn func(el net.Conn) error) error {
@@ -69,5 +182,6 @@ func (cg *connGroup) GroupStats() GroupStats {
 }
 
 func (cg *connGroup) Close() error {
+	cg.stopIdleCheck()
 	return cg.raw.Close()
 }
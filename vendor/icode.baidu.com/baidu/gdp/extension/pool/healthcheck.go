@@ -0,0 +1,98 @@
+// Copyright(C) 2021 Baidu Inc. All Rights Reserved.
+// Author: Wei Du (duwei04@baidu.com)
+// Date: 2021/6/2
+
+package pool
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// HealthChecker 连接健康检查策略，用于判断一个连接是否仍然可用。
+// 返回非 nil 错误表示 conn 已不可用，调用方应该丢弃该连接
+type HealthChecker interface {
+	Check(ctx context.Context, conn net.Conn) error
+}
+
+// HealthCheckerFunc 允许把普通函数适配为 HealthChecker
+type HealthCheckerFunc func(ctx context.Context, conn net.Conn) error
+
+// Check 实现 HealthChecker
+func (f HealthCheckerFunc) Check(ctx context.Context, conn net.Conn) error {
+	return f(ctx, conn)
+}
+
+// SyscallHealthChecker 通过 connCheck 做底层 fd 的 syscall 探测，
+// 是 pConn.PEActive 历史版本里硬编码的默认行为
+type SyscallHealthChecker struct{}
+
+// Check 实现 HealthChecker
+func (SyscallHealthChecker) Check(ctx context.Context, conn net.Conn) error {
+	if cr, ok := conn.(interface{ Raw() net.Conn }); ok {
+		conn = cr.Raw()
+	}
+	return connCheck(conn)
+}
+
+// DefaultHealthChecker 默认的健康检查策略，Option.HealthChecker 未设置时使用
+var DefaultHealthChecker HealthChecker = SyscallHealthChecker{}
+
+// ProbeFunc 向 conn 发送一次协议探测（如发送 PING 并等待回复），
+// 用于业务自己实现的应用层存活检测
+type ProbeFunc func(ctx context.Context, conn net.Conn) error
+
+// ProbeHealthChecker 基于调用方提供的协议探测做健康检查，比 SyscallHealthChecker
+// 更准确，但会产生真实的读写开销，一般配合 Option.IdleCheckInterval 后台异步执行
+type ProbeHealthChecker struct {
+	// Probe 执行一次协议层的读写探测
+	Probe ProbeFunc
+
+	// Timeout 单次探测的超时时间，<=0 表示不设置超时
+	Timeout time.Duration
+}
+
+// NewProbeHealthChecker 创建一个 ProbeHealthChecker
+func NewProbeHealthChecker(probe ProbeFunc, timeout time.Duration) *ProbeHealthChecker {
+	return &ProbeHealthChecker{Probe: probe, Timeout: timeout}
+}
+
+// Check 实现 HealthChecker
+func (p *ProbeHealthChecker) Check(ctx context.Context, conn net.Conn) error {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	return p.Probe(ctx, conn)
+}
+
+// CompositeHealthChecker 依次执行多个 HealthChecker，任意一个失败即判定为不健康
+type CompositeHealthChecker []HealthChecker
+
+// NewCompositeHealthChecker 创建一个 CompositeHealthChecker
+func NewCompositeHealthChecker(checkers ...HealthChecker) CompositeHealthChecker {
+	return CompositeHealthChecker(checkers)
+}
+
+// Check 实现 HealthChecker，按顺序执行，遇到第一个错误即返回
+func (c CompositeHealthChecker) Check(ctx context.Context, conn net.Conn) error {
+	for _, checker := range c {
+		if checker == nil {
+			continue
+		}
+		if err := checker.Check(ctx, conn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveHealthChecker 获取 opt 配置的 HealthChecker，未配置时回退到默认的 syscall 探测
+func resolveHealthChecker(opt Option) HealthChecker {
+	if opt.HealthChecker != nil {
+		return opt.HealthChecker
+	}
+	return DefaultHealthChecker
+}
@@ -0,0 +1,26 @@
+// Copyright(C) 2021 Baidu Inc. All Rights Reserved.
+// Author: Wei Du (duwei04@baidu.com)
+// Date: 2021/3/29
+
+package pool
+
+import "time"
+
+// Option 连接池的配置项
+type Option struct {
+	// MaxIdleTime 连接最大空闲时间，超过该时间未被使用的连接会被判定为失效，<=0 表示不限制
+	MaxIdleTime time.Duration
+
+	// MaxLifeTime 连接最大生命周期，从创建时刻起超过该时长就会被判定为失效，<=0 表示不限制
+	MaxLifeTime time.Duration
+
+	// HealthChecker 连接的健康检查策略，用于 pConn.PEActive 以及后台巡检，
+	// 为 nil 时使用 DefaultHealthChecker（即原来硬编码的 connCheck syscall 探测）
+	HealthChecker HealthChecker
+
+	// IdleCheckInterval 后台巡检空闲连接的时间间隔，<=0 表示不启动后台巡检协程
+	IdleCheckInterval time.Duration
+
+	// MinIdleConns 后台巡检时尝试补充到的最小空闲连接数，<=0 表示不主动补充
+	MinIdleConns int
+}
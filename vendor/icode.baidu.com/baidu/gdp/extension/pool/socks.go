@@ -0,0 +1,298 @@
+// Copyright(C) 2021 Baidu Inc. All Rights Reserved.
+// Author: Wei Du (duwei04@baidu.com)
+// Date: 2021/5/18
+
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	socksVersion5 = 0x05
+
+	socksAuthNone         = 0x00
+	socksAuthUserPass     = 0x02
+	socksAuthNoAcceptable = 0xff
+
+	socksUserPassVersion = 0x01
+
+	socksCmdConnect = 0x01
+
+	socksAddrIPv4   = 0x01
+	socksAddrDomain = 0x03
+	socksAddrIPv6   = 0x04
+)
+
+// SOCKSAuth SOCKS5 用户名密码认证信息，参见 RFC 1929
+type SOCKSAuth struct {
+	Username string
+	Password string
+}
+
+// REP 字段到具体错误类型的映射，方便调用方针对性处理
+var (
+	ErrSOCKSGeneralFailure     = errors.New("pool: socks server general failure")
+	ErrSOCKSRuleNotAllowed     = errors.New("pool: socks connection not allowed by ruleset")
+	ErrSOCKSNetworkUnreachable = errors.New("pool: socks network unreachable")
+	ErrSOCKSHostUnreachable    = errors.New("pool: socks host unreachable")
+	ErrSOCKSConnectionRefused  = errors.New("pool: socks connection refused by destination host")
+	ErrSOCKSTTLExpired         = errors.New("pool: socks TTL expired")
+	ErrSOCKSCmdNotSupported    = errors.New("pool: socks command not supported")
+	ErrSOCKSAddrNotSupported   = errors.New("pool: socks address type not supported")
+	ErrSOCKSAuthFailed         = errors.New("pool: socks username/password authentication failed")
+	ErrSOCKSNoAcceptableAuth   = errors.New("pool: socks server accepted no offered auth method")
+)
+
+var socksReplyErrors = map[byte]error{
+	0x00: nil,
+	0x01: ErrSOCKSGeneralFailure,
+	0x02: ErrSOCKSRuleNotAllowed,
+	0x03: ErrSOCKSNetworkUnreachable,
+	0x04: ErrSOCKSHostUnreachable,
+	0x05: ErrSOCKSConnectionRefused,
+	0x06: ErrSOCKSTTLExpired,
+	0x07: ErrSOCKSCmdNotSupported,
+	0x08: ErrSOCKSAddrNotSupported,
+}
+
+// SOCKSDialer 通过 SOCKS5 代理与目标地址建立一条 CONNECT 隧道
+type SOCKSDialer struct {
+	// ProxyAddr SOCKS5 代理地址，如 "127.0.0.1:1080"
+	ProxyAddr string
+
+	// Auth 用户名密码认证信息，为 nil 时使用 no-auth 方式协商
+	Auth *SOCKSAuth
+
+	// Timeout 建立到代理以及完成握手的超时时间，<=0 表示不设置超时
+	Timeout time.Duration
+
+	// Dialer 建立到代理服务器的底层连接方式，为 nil 时使用 &net.Dialer{}
+	Dialer *net.Dialer
+}
+
+// DialContext 拨号到 ProxyAddr 并通过 SOCKS5 握手使代理与 target 建立连接，
+// 返回的 net.Conn 读写的是代理转发过去的 target 流量
+func (d *SOCKSDialer) DialContext(ctx context.Context, target net.Addr) (net.Conn, error) {
+	dialer := d.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", d.ProxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.handshake(ctx, conn, target); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &socksConn{Conn: conn}, nil
+}
+
+// handshake 完成 SOCKS5 握手，ctx 与 DialContext 中拨号用的是同一个，
+// 这样 d.Timeout 限制的是拨号 + 握手的总耗时，而不是握手单独再给一份新的预算
+func (d *SOCKSDialer) handshake(ctx context.Context, conn net.Conn, target net.Addr) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	if err := d.negotiateAuth(conn); err != nil {
+		return err
+	}
+	return d.connect(conn, target)
+}
+
+func (d *SOCKSDialer) negotiateAuth(conn net.Conn) error {
+	methods := []byte{socksAuthNone}
+	if d.Auth != nil {
+		methods = []byte{socksAuthUserPass}
+	}
+
+	req := make([]byte, 0, 2+len(methods))
+	req = append(req, socksVersion5, byte(len(methods)))
+	req = append(req, methods...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != socksVersion5 {
+		return fmt.Errorf("pool: unexpected socks version %d in method reply", reply[0])
+	}
+
+	switch reply[1] {
+	case socksAuthNone:
+		return nil
+	case socksAuthUserPass:
+		if d.Auth == nil {
+			return ErrSOCKSNoAcceptableAuth
+		}
+		return d.authUserPass(conn)
+	case socksAuthNoAcceptable:
+		return ErrSOCKSNoAcceptableAuth
+	default:
+		return fmt.Errorf("pool: socks server selected unsupported auth method %d", reply[1])
+	}
+}
+
+func (d *SOCKSDialer) authUserPass(conn net.Conn) error {
+	user := []byte(d.Auth.Username)
+	pass := []byte(d.Auth.Password)
+
+	req := make([]byte, 0, 3+len(user)+len(pass))
+	req = append(req, socksUserPassVersion, byte(len(user)))
+	req = append(req, user...)
+	req = append(req, byte(len(pass)))
+	req = append(req, pass...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[1] != 0x00 {
+		return ErrSOCKSAuthFailed
+	}
+	return nil
+}
+
+func (d *SOCKSDialer) connect(conn net.Conn, target net.Addr) error {
+	addrBytes, err := socksEncodeAddr(target)
+	if err != nil {
+		return err
+	}
+
+	req := make([]byte, 0, 3+len(addrBytes))
+	req = append(req, socksVersion5, socksCmdConnect, 0x00)
+	req = append(req, addrBytes...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	return socksReadReply(conn)
+}
+
+// socksEncodeAddr 将 net.Addr 编码为 SOCKS5 请求中的 ATYP + DST.ADDR + DST.PORT，
+// 依次尝试 IPv4、IPv6，都不是的话当成域名处理
+func socksEncodeAddr(addr net.Addr) ([]byte, error) {
+	host, portStr, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil, fmt.Errorf("pool: invalid socks target address %q: %w", addr.String(), err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("pool: invalid socks target port %q: %w", portStr, err)
+	}
+
+	var buf []byte
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			buf = append(buf, socksAddrIPv4)
+			buf = append(buf, ip4...)
+		} else {
+			buf = append(buf, socksAddrIPv6)
+			buf = append(buf, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return nil, fmt.Errorf("pool: socks domain name too long: %q", host)
+		}
+		buf = append(buf, socksAddrDomain, byte(len(host)))
+		buf = append(buf, host...)
+	}
+
+	buf = append(buf, byte(port>>8), byte(port))
+	return buf, nil
+}
+
+func socksReadReply(conn net.Conn) error {
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return err
+	}
+	if head[0] != socksVersion5 {
+		return fmt.Errorf("pool: unexpected socks version %d in connect reply", head[0])
+	}
+	if err, known := socksReplyErrors[head[1]]; known {
+		if err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("pool: unknown socks reply code %d", head[1])
+	}
+
+	var addrLen int
+	switch head[3] {
+	case socksAddrIPv4:
+		addrLen = net.IPv4len
+	case socksAddrIPv6:
+		addrLen = net.IPv6len
+	case socksAddrDomain:
+		lenByte := make([]byte, 1)
+		if _, err := io.ReadFull(conn, lenByte); err != nil {
+			return err
+		}
+		addrLen = int(lenByte[0])
+	default:
+		return fmt.Errorf("pool: unknown socks address type %d in connect reply", head[3])
+	}
+
+	// 丢弃 BND.ADDR + BND.PORT，调用方不关心代理侧绑定的地址
+	if _, err := io.ReadFull(conn, make([]byte, addrLen+2)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// socksConn 包装完成 SOCKS5 握手后的 net.Conn，暴露 Raw 以便 getRawConn
+// 拿到的是完成握手的那条底层 TCP 连接，而不是把 socksConn 这层包装当成最底层的 socket
+type socksConn struct {
+	net.Conn
+}
+
+// Raw 返回握手完成后的底层 TCP 连接
+func (c *socksConn) Raw() net.Conn {
+	return c.Conn
+}
+
+// NewSOCKSConnPool 创建一个通过 SOCKS5 代理访问 target 的 ConnPool，
+// 每次从池中拨号新连接时都会先与代理完成一次 SOCKS5 握手
+func NewSOCKSConnPool(option *Option, proxyAddr string, target net.Addr, auth *SOCKSAuth) ConnPool {
+	dialer := &SOCKSDialer{ProxyAddr: proxyAddr, Auth: auth}
+	return NewConnPool(option, func(ctx context.Context) (net.Conn, error) {
+		return dialer.DialContext(ctx, target)
+	})
+}
+
+// SOCKSGroupNewConnFunc 构造一个按 net.Addr 分组、统一经由同一个 SOCKS5
+// 代理拨号的 GroupNewConnFunc，可直接传给 NewConnPoolGroup
+func SOCKSGroupNewConnFunc(proxyAddr string, auth *SOCKSAuth) GroupNewConnFunc {
+	dialer := &SOCKSDialer{ProxyAddr: proxyAddr, Auth: auth}
+	return func(addr net.Addr) NewConnFunc {
+		return func(ctx context.Context) (net.Conn, error) {
+			return dialer.DialContext(ctx, addr)
+		}
+	}
+}
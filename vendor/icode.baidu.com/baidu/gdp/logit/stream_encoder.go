@@ -0,0 +1,341 @@
+// Copyright(C) 2020 Baidu Inc. All Rights Reserved.
+// Author: Chen Xin (chenxin@baidu.com)
+// Date: 2020/05/08
+
+package logit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// StreamingJSONEncoder 以流式方式将字段直接写入内部 buffer 的 Encoder，
+// 相比 JSONEncoder 把每个字段存进 map[string]interface{} 再整体 json.Marshal，
+// StreamingJSONEncoder 在 AddXXX 时就把 `,"key":value` 片段追加到 buffer 里，
+// 数值类型通过 strconv.AppendXXX 直接写入 buffer，不经过 interface{} 装箱
+//
+// Reset 只是把 buffer 截断为 0 长度而不释放底层数组，所以配合 EncoderPool
+// 使用时，同一个 StreamingJSONEncoder 的底层数组会在多行日志之间被复用
+type StreamingJSONEncoder struct {
+	buf bytes.Buffer
+	err error // AddXXX 阶段遇到的不可恢复错误，比如非法的 NaN/Inf 浮点数
+
+	LineBreak []byte // 换行符
+}
+
+// NewStreamingJSONEncoder 打包输出为 json 格式，一行一个 json，多行之间以 "\n" 分割
+func NewStreamingJSONEncoder() FieldEncoder {
+	return &StreamingJSONEncoder{
+		LineBreak: []byte("\n"),
+	}
+}
+
+// WriteTo 写入
+func (e *StreamingJSONEncoder) WriteTo(w io.Writer) (int64, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	var total int64
+
+	n, err := w.Write([]byte{'{'})
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+
+	if e.buf.Len() > 0 {
+		// buf 里每个字段都带着前导逗号，第一个字段的逗号需要跳过
+		n, err = w.Write(e.buf.Bytes()[1:])
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	n, err = w.Write([]byte{'}'})
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+
+	if len(e.LineBreak) > 0 {
+		n, err = w.Write(e.LineBreak)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+func (e *StreamingJSONEncoder) writeKey(key string) {
+	e.buf.WriteByte(',')
+	e.buf.WriteByte('"')
+	writeJSONEscapedString(&e.buf, key)
+	e.buf.WriteByte('"')
+	e.buf.WriteByte(':')
+}
+
+func (e *StreamingJSONEncoder) appendInt(value int64) {
+	var scratch [20]byte
+	e.buf.Write(strconv.AppendInt(scratch[:0], value, 10))
+}
+
+func (e *StreamingJSONEncoder) appendUint(value uint64) {
+	var scratch [20]byte
+	e.buf.Write(strconv.AppendUint(scratch[:0], value, 10))
+}
+
+func (e *StreamingJSONEncoder) appendFloat(value float64, bitSize int) {
+	// strconv.AppendFloat 对 NaN/+Inf/-Inf 会写出 "NaN"/"+Inf"/"-Inf" 这种非法的
+	// JSON token，和 json.Marshal 对同样输入返回 error 的行为保持一致，记录下来，
+	// 让 WriteTo 直接报错而不是悄悄吐出损坏的 JSON
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		if e.err == nil {
+			e.err = fmt.Errorf("logit: unsupported value: %v", value)
+		}
+		e.buf.WriteByte('0')
+		return
+	}
+
+	var scratch [32]byte
+	e.buf.Write(strconv.AppendFloat(scratch[:0], value, 'f', -1, bitSize))
+}
+
+// AddBinary 二进制字段，按 base64 编码写入，和 encoding/json 对 []byte 的处理保持一致
+func (e *StreamingJSONEncoder) AddBinary(key string, value []byte) {
+	e.writeKey(key)
+	e.buf.WriteByte('"')
+	enc := base64.NewEncoder(base64.StdEncoding, &e.buf)
+	_, _ = enc.Write(value)
+	_ = enc.Close()
+	e.buf.WriteByte('"')
+}
+
+// AddBool bool类型
+func (e *StreamingJSONEncoder) AddBool(key string, value bool) {
+	e.writeKey(key)
+	if value {
+		e.buf.WriteString("true")
+	} else {
+		e.buf.WriteString("false")
+	}
+}
+
+// AddByteString UTF-8编码的字符串
+func (e *StreamingJSONEncoder) AddByteString(key string, value []byte) {
+	e.writeKey(key)
+	e.buf.WriteByte('"')
+	writeJSONEscapedString(&e.buf, string(value))
+	e.buf.WriteByte('"')
+}
+
+// AddDuration 时间间隔，单位毫秒
+func (e *StreamingJSONEncoder) AddDuration(key string, value time.Duration) {
+	e.writeKey(key)
+	e.appendFloat(float64(value.Nanoseconds())/float64(time.Millisecond), 64)
+}
+
+// AddFloat64 Float64
+func (e *StreamingJSONEncoder) AddFloat64(key string, value float64) {
+	e.writeKey(key)
+	e.appendFloat(value, 64)
+}
+
+// AddFloat32 Float32
+func (e *StreamingJSONEncoder) AddFloat32(key string, value float32) {
+	e.writeKey(key)
+	e.appendFloat(float64(value), 32)
+}
+
+// AddInt Int
+func (e *StreamingJSONEncoder) AddInt(key string, value int) {
+	e.writeKey(key)
+	e.appendInt(int64(value))
+}
+
+// AddInt64 Int64
+func (e *StreamingJSONEncoder) AddInt64(key string, value int64) {
+	e.writeKey(key)
+	e.appendInt(value)
+}
+
+// AddInt32 Int32
+func (e *StreamingJSONEncoder) AddInt32(key string, value int32) {
+	e.writeKey(key)
+	e.appendInt(int64(value))
+}
+
+// AddInt16 Int16
+func (e *StreamingJSONEncoder) AddInt16(key string, value int16) {
+	e.writeKey(key)
+	e.appendInt(int64(value))
+}
+
+// AddInt8 Int8
+func (e *StreamingJSONEncoder) AddInt8(key string, value int8) {
+	e.writeKey(key)
+	e.appendInt(int64(value))
+}
+
+// AddString String
+func (e *StreamingJSONEncoder) AddString(key, value string) {
+	e.writeKey(key)
+	e.buf.WriteByte('"')
+	writeJSONEscapedString(&e.buf, value)
+	e.buf.WriteByte('"')
+}
+
+// AddTime Time
+func (e *StreamingJSONEncoder) AddTime(key string, value time.Time) {
+	e.writeKey(key)
+	e.buf.WriteByte('"')
+	e.buf.WriteString(value.Format(time.RFC3339Nano))
+	e.buf.WriteByte('"')
+}
+
+// AddUint Uint
+func (e *StreamingJSONEncoder) AddUint(key string, value uint) {
+	e.writeKey(key)
+	e.appendUint(uint64(value))
+}
+
+// AddUint64 Uint64
+func (e *StreamingJSONEncoder) AddUint64(key string, value uint64) {
+	e.writeKey(key)
+	e.appendUint(value)
+}
+
+// AddUint32 Uint32
+func (e *StreamingJSONEncoder) AddUint32(key string, value uint32) {
+	e.writeKey(key)
+	e.appendUint(uint64(value))
+}
+
+// AddUint16 Uint16
+func (e *StreamingJSONEncoder) AddUint16(key string, value uint16) {
+	e.writeKey(key)
+	e.appendUint(uint64(value))
+}
+
+// AddUint8 Uint8
+func (e *StreamingJSONEncoder) AddUint8(key string, value uint8) {
+	e.writeKey(key)
+	e.appendUint(uint64(value))
+}
+
+// AddUintptr Uintptr
+func (e *StreamingJSONEncoder) AddUintptr(key string, value uintptr) {
+	e.writeKey(key)
+	e.appendUint(uint64(value))
+}
+
+// AddError  Error
+func (e *StreamingJSONEncoder) AddError(key string, value error) {
+	e.writeKey(key)
+	if value == nil {
+		e.buf.WriteString("null")
+		return
+	}
+	e.buf.WriteByte('"')
+	writeJSONEscapedString(&e.buf, value.Error())
+	e.buf.WriteByte('"')
+}
+
+// AddReflected uses reflection to serialize arbitrary objects, so it can be
+// slow and allocation-heavy.
+func (e *StreamingJSONEncoder) AddReflected(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil { // 忽略json marshal失败，将错误信息写到error
+		e.AddError(key, err)
+		return nil
+	}
+	e.writeKey(key)
+	e.buf.Write(b)
+	return nil
+}
+
+// Reset 重置，只是把 buffer 截断为 0 长度，并不释放底层数组，
+// 这样从 EncoderPool 里再次 Get 出来时可以复用同一块内存
+func (e *StreamingJSONEncoder) Reset() {
+	e.buf.Reset()
+	e.err = nil
+}
+
+var _ FieldEncoder = (*StreamingJSONEncoder)(nil)
+
+// writeJSONEscapedString 把 s 按 JSON 字符串的转义规则写入 buf，
+// 调用方需要自己写好包裹用的双引号
+func writeJSONEscapedString(buf *bytes.Buffer, s string) {
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			if start < i {
+				buf.WriteString(s[start:i])
+			}
+			switch b {
+			case '"':
+				buf.WriteString(`\"`)
+			case '\\':
+				buf.WriteString(`\\`)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[b>>4])
+				buf.WriteByte(hexDigits[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			if start < i {
+				buf.WriteString(s[start:i])
+			}
+			buf.WriteString(`�`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	if start < len(s) {
+		buf.WriteString(s[start:])
+	}
+}
+
+const encoderPoolNameJSONStream = "json_stream"
+
+// DefaultStreamingJSONEncoderPool 默认的 streaming json encoder pool
+var DefaultStreamingJSONEncoderPool = NewEncoderPool(func() FieldEncoder {
+	return NewStreamingJSONEncoder()
+})
+
+func init() {
+	if err := RegisterEncoderPool(encoderPoolNameJSONStream, DefaultStreamingJSONEncoderPool); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright(C) 2020 Baidu Inc. All Rights Reserved.
+// Author: Chen Xin (chenxin@baidu.com)
+// Date: 2020/05/08
+
+package logit
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func benchmarkEncoder(b *testing.B, enc FieldEncoder) {
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var buf bytes.Buffer
+	now := time.Unix(1600000000, 0)
+	errBoom := errors.New("boom")
+
+	for i := 0; i < b.N; i++ {
+		enc.AddString("method", "GET")
+		enc.AddString("path", "/api/v1/user")
+		enc.AddInt("status", 200)
+		enc.AddInt64("cost", 12)
+		enc.AddFloat64("ratio", 0.99)
+		enc.AddBool("ok", true)
+		enc.AddTime("time", now)
+		enc.AddError("err", errBoom)
+
+		buf.Reset()
+		if _, err := enc.WriteTo(&buf); err != nil {
+			b.Fatal(err)
+		}
+		enc.Reset()
+	}
+}
+
+// BenchmarkJSONEncoder 对比基准，JSONEncoder 先存 map[string]interface{}
+// 再整体 json.Marshal
+func BenchmarkJSONEncoder(b *testing.B) {
+	benchmarkEncoder(b, NewJSONEncoder())
+}
+
+// BenchmarkStreamingJSONEncoder StreamingJSONEncoder 的分配情况，
+// 预期 allocs/op 明显低于 BenchmarkJSONEncoder
+func BenchmarkStreamingJSONEncoder(b *testing.B) {
+	benchmarkEncoder(b, NewStreamingJSONEncoder())
+}
@@ -0,0 +1,104 @@
+// Copyright(C) 2020 Baidu Inc. All Rights Reserved.
+// Author: Chen Xin (chenxin@baidu.com)
+// Date: 2020/05/08
+
+package logit
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestStreamingJSONEncoderEscaping(t *testing.T) {
+	enc := NewStreamingJSONEncoder().(*StreamingJSONEncoder)
+
+	enc.AddString("quote", `say "hi"`)
+	enc.AddString("backslash", `a\b`)
+	enc.AddString("control", "line1\nline2\ttab")
+	enc.AddString("invalid_utf8", "a\xffb")
+
+	var buf bytes.Buffer
+	if _, err := enc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("output is not valid json: %s", buf.String())
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal failed: %v, json: %s", err, buf.String())
+	}
+
+	cases := map[string]string{
+		"quote":        `say "hi"`,
+		"backslash":    `a\b`,
+		"control":      "line1\nline2\ttab",
+		"invalid_utf8": "a�b",
+	}
+	for key, want := range cases {
+		if got[key] != want {
+			t.Errorf("%s = %q, want %q", key, got[key], want)
+		}
+	}
+}
+
+func TestStreamingJSONEncoderNonFiniteFloat(t *testing.T) {
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		enc := NewStreamingJSONEncoder().(*StreamingJSONEncoder)
+		enc.AddFloat64("value", v)
+
+		var buf bytes.Buffer
+		if _, err := enc.WriteTo(&buf); err == nil {
+			t.Errorf("WriteTo(%v): want error, got nil, output %q", v, buf.String())
+		}
+	}
+}
+
+func TestStreamingJSONEncoderResetKeepsCapacity(t *testing.T) {
+	enc := NewStreamingJSONEncoder().(*StreamingJSONEncoder)
+
+	for i := 0; i < 64; i++ {
+		enc.AddString("k", "some reasonably long field value to grow the buffer")
+	}
+	capBefore := cap(enc.buf.Bytes())
+	if capBefore == 0 {
+		t.Fatal("buffer did not grow")
+	}
+
+	enc.Reset()
+
+	if enc.buf.Len() != 0 {
+		t.Fatalf("buf len after Reset = %d, want 0", enc.buf.Len())
+	}
+	if cap(enc.buf.Bytes()) != capBefore {
+		t.Fatalf("buf capacity changed after Reset: before=%d after=%d", capBefore, cap(enc.buf.Bytes()))
+	}
+}
+
+func TestStreamingJSONEncoderPoolReuseKeepsCapacity(t *testing.T) {
+	pool := NewEncoderPool(func() FieldEncoder {
+		return NewStreamingJSONEncoder()
+	})
+
+	enc := pool.Get().(*StreamingJSONEncoder)
+	for i := 0; i < 64; i++ {
+		enc.AddString("k", "some reasonably long field value to grow the buffer")
+	}
+	capBefore := cap(enc.buf.Bytes())
+	pool.Put(enc)
+
+	got := pool.Get().(*StreamingJSONEncoder)
+	if got != enc {
+		t.Skip("sync.Pool handed back a different instance, can't assert capacity reuse here")
+	}
+	if got.buf.Len() != 0 {
+		t.Fatalf("buf len after pool Put/Get = %d, want 0", got.buf.Len())
+	}
+	if cap(got.buf.Bytes()) != capBefore {
+		t.Fatalf("buf capacity changed via pool Put/Get: before=%d after=%d", capBefore, cap(got.buf.Bytes()))
+	}
+}